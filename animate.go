@@ -0,0 +1,44 @@
+package main
+
+import (
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+// animationFrames renders numFrames frames of art, sweeping t from 0 to 2π
+// so that any time-aware node (Sin, Mix, FractalNoise) completes one full
+// cycle and loops seamlessly. Each frame reuses renderFrame's worker-pool
+// split, so the cost of an animation is just numFrames single-frame renders.
+func animationFrames(art ColorFunc, size, numFrames int) []*image.RGBA {
+	frames := make([]*image.RGBA, numFrames)
+	for i := 0; i < numFrames; i++ {
+		t := 2 * math.Pi * float64(i) / float64(numFrames)
+		frames[i] = renderFrame(art, size, t)
+	}
+	return frames
+}
+
+// encodeGIF quantizes each RGBA frame onto the web-safe palette and writes
+// an animated GIF to w. delay is the per-frame delay in 1/100ths of a
+// second (GIF's native unit); loopCount is the GIF loop count, where 0
+// means loop forever.
+func encodeGIF(w io.Writer, frames []*image.RGBA, delay, loopCount int) error {
+	g := &gif.GIF{
+		Image:     make([]*image.Paletted, len(frames)),
+		Delay:     make([]int, len(frames)),
+		LoopCount: loopCount,
+	}
+
+	for i, frame := range frames {
+		paletted := image.NewPaletted(frame.Bounds(), palette.WebSafe)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+		g.Image[i] = paletted
+		g.Delay[i] = delay
+	}
+
+	return gif.EncodeAll(w, g)
+}
@@ -6,13 +6,15 @@ import (
 	"image/color"
 	"math"
 	"math/rand"
-  "runtime"
+	"runtime"
 	"sync"
 )
 
-// ColorFunc defines a function that computes RGB values based on x, y.
+// ColorFunc defines a function that computes RGB values based on x, y, and
+// optionally a time parameter t. Nodes that don't care about time simply
+// ignore it, which keeps single-frame rendering (t=0) identical to before.
 type ColorFunc interface {
-	Eval(x, y float64) (r, g, b float64)
+	Eval(x, y, t float64) (r, g, b float64)
 	String() string
 }
 
@@ -21,7 +23,7 @@ type Constant struct {
 	r, g, b float64
 }
 
-func (c *Constant) Eval(x, y float64) (float64, float64, float64) {
+func (c *Constant) Eval(x, y, t float64) (float64, float64, float64) {
 	return c.r, c.g, c.b
 }
 
@@ -32,7 +34,7 @@ func (c *Constant) String() string {
 // VariableX represents the X coordinate as a color.
 type VariableX struct{}
 
-func (v *VariableX) Eval(x, y float64) (float64, float64, float64) {
+func (v *VariableX) Eval(x, y, t float64) (float64, float64, float64) {
 	return x, x, x
 }
 
@@ -43,7 +45,7 @@ func (v *VariableX) String() string {
 // VariableY represents the Y coordinate as a color.
 type VariableY struct{}
 
-func (v *VariableY) Eval(x, y float64) (float64, float64, float64) {
+func (v *VariableY) Eval(x, y, t float64) (float64, float64, float64) {
 	return y, y, y
 }
 
@@ -51,15 +53,18 @@ func (v *VariableY) String() string {
 	return "VariableY"
 }
 
-// Sin represents a sine wave operation on a subexpression.
+// Sin represents a sine wave operation on a subexpression. When evaluated
+// with a nonzero t, the time advances the phase, giving the same wave a
+// per-frame offset instead of a static look.
 type Sin struct {
 	phase, freq float64
 	sub         ColorFunc
 }
 
-func (s *Sin) Eval(x, y float64) (float64, float64, float64) {
-	r, g, b := s.sub.Eval(x, y)
-	return math.Sin(s.phase + s.freq*r), math.Sin(s.phase + s.freq*g), math.Sin(s.phase + s.freq*b)
+func (s *Sin) Eval(x, y, t float64) (float64, float64, float64) {
+	r, g, b := s.sub.Eval(x, y, t)
+	phase := s.phase + t
+	return math.Sin(phase + s.freq*r), math.Sin(phase + s.freq*g), math.Sin(phase + s.freq*b)
 }
 
 func (s *Sin) String() string {
@@ -71,9 +76,9 @@ type Product struct {
 	left, right ColorFunc
 }
 
-func (p *Product) Eval(x, y float64) (float64, float64, float64) {
-	r1, g1, b1 := p.left.Eval(x, y)
-	r2, g2, b2 := p.right.Eval(x, y)
+func (p *Product) Eval(x, y, t float64) (float64, float64, float64) {
+	r1, g1, b1 := p.left.Eval(x, y, t)
+	r2, g2, b2 := p.right.Eval(x, y, t)
 	return r1 * r2, g1 * g2, b1 * b2
 }
 
@@ -81,21 +86,28 @@ func (p *Product) String() string {
 	return fmt.Sprintf("Product(left=%s, right=%s)", p.left.String(), p.right.String())
 }
 
-// Mix blends two subexpressions based on a fixed weight.
+// Mix blends two subexpressions based on a weight. If animated is set, the
+// weight itself oscillates as a sine of t instead of staying fixed, which
+// makes a blend slowly crossfade between its two sides over an animation.
 type Mix struct {
-	w     float64
-	left  ColorFunc
-	right ColorFunc
+	w        float64
+	animated bool
+	left     ColorFunc
+	right    ColorFunc
 }
 
-func (m *Mix) Eval(x, y float64) (float64, float64, float64) {
-	r1, g1, b1 := m.left.Eval(x, y)
-	r2, g2, b2 := m.right.Eval(x, y)
-	return m.w*r1 + (1-m.w)*r2, m.w*g1 + (1-m.w)*g2, m.w*b1 + (1-m.w)*b2
+func (m *Mix) Eval(x, y, t float64) (float64, float64, float64) {
+	r1, g1, b1 := m.left.Eval(x, y, t)
+	r2, g2, b2 := m.right.Eval(x, y, t)
+	w := m.w
+	if m.animated {
+		w = 0.5 + 0.5*math.Sin(t)
+	}
+	return w*r1 + (1-w)*r2, w*g1 + (1-w)*g2, w*b1 + (1-w)*b2
 }
 
 func (m *Mix) String() string {
-	return fmt.Sprintf("Mix(w=%.2f, left=%s, right=%s)", m.w, m.left.String(), m.right.String())
+	return fmt.Sprintf("Mix(w=%.2f, animated=%t, left=%s, right=%s)", m.w, m.animated, m.left.String(), m.right.String())
 }
 
 // Well creates a well-like pattern for the subexpression.
@@ -103,8 +115,8 @@ type Well struct {
 	sub ColorFunc
 }
 
-func (w *Well) Eval(x, y float64) (float64, float64, float64) {
-	r, g, b := w.sub.Eval(x, y)
+func (w *Well) Eval(x, y, t float64) (float64, float64, float64) {
+	r, g, b := w.sub.Eval(x, y, t)
 	well := func(v float64) float64 {
 		return 1 - 2/(1+math.Pow(v, 2))
 	}
@@ -115,16 +127,17 @@ func (w *Well) String() string {
 	return fmt.Sprintf("Well(sub=%s)", w.sub.String())
 }
 
-// FractalNoise generates a fractal noise pattern.
+// FractalNoise generates a fractal noise pattern. t nudges the smoothing
+// phase so an animated render drifts instead of just rescaling in place.
 type FractalNoise struct {
 	scale float64
 	sub   ColorFunc
 }
 
-func (f *FractalNoise) Eval(x, y float64) (float64, float64, float64) {
-	r, g, b := f.sub.Eval(x*f.scale, y*f.scale)
+func (f *FractalNoise) Eval(x, y, t float64) (float64, float64, float64) {
+	r, g, b := f.sub.Eval(x*f.scale, y*f.scale, t)
 	smooth := func(v float64) float64 {
-		return 0.5 * (math.Sin(5*v) + math.Cos(5*v))
+		return 0.5 * (math.Sin(5*v+t) + math.Cos(5*v+t))
 	}
 	return smooth(r), smooth(g), smooth(b)
 }
@@ -149,6 +162,32 @@ func Generate(minDepth, maxDepth int) ColorFunc {
 			}
 		}
 	}
+
+	// HSL-space nodes are included with lower probability than the core
+	// structural nodes below, since they tend to dominate the look of a
+	// tree whenever they appear.
+	if rand.Float64() < 0.1 {
+		switch rand.Intn(4) {
+		case 0:
+			return &HSL{sub: Generate(minDepth-1, maxDepth-1)}
+		case 1:
+			return &HSLToRGB{sub: &Hue{
+				sub:   &RGBToHSL{sub: Generate(minDepth-1, maxDepth-1)},
+				shift: rand.Float64() * 360,
+			}}
+		case 2:
+			return &HSLToRGB{sub: &Saturate{
+				sub:    &RGBToHSL{sub: Generate(minDepth-1, maxDepth-1)},
+				factor: 0.5 + rand.Float64()*1.5,
+			}}
+		default:
+			return &HSLToRGB{sub: &Rotate{
+				sub:   &RGBToHSL{sub: Generate(minDepth-1, maxDepth-1)},
+				speed: 0.5 + rand.Float64()*2.0,
+			}}
+		}
+	}
+
 	switch rand.Intn(4) { // Removed Kaleidoscope and Spiral
 	case 0:
 		return &Sin{
@@ -158,9 +197,10 @@ func Generate(minDepth, maxDepth int) ColorFunc {
 		}
 	case 1:
 		return &Mix{
-			w:     rand.Float64(),
-			left:  Generate(minDepth-1, maxDepth-1),
-			right: Generate(minDepth-1, maxDepth-1),
+			w:        rand.Float64(),
+			animated: rand.Float64() < 0.3,
+			left:     Generate(minDepth-1, maxDepth-1),
+			right:    Generate(minDepth-1, maxDepth-1),
 		}
 	case 2:
 		return &Product{
@@ -176,51 +216,23 @@ func Generate(minDepth, maxDepth int) ColorFunc {
 }
 
 // CreateImage generates the random art as an image using multithreading.
-func CreateImage(seed string, size int) image.Image {
+// It also returns the expression tree that produced it, so callers that
+// want to persist or rerender the result (the gallery, for instance)
+// don't have to regenerate it from the seed and hope Generate hasn't
+// changed in the meantime.
+func CreateImage(seed string, size int) (image.Image, ColorFunc) {
 	rand.Seed(int64(hash(seed)))
 	var img *image.RGBA
+	var art ColorFunc
 	var variance float64
 
 	// Generate images until a threshold is met
 	for attempts := 0; attempts < 3; attempts++ { // Retry up to 5 times
-		img = image.NewRGBA(image.Rect(0, 0, size, size))
-		art := Generate(10, 30)
+		art = Generate(10, 30)
 
 		fmt.Printf("Expression tree for seed '%s':\n%s\n", seed, art.String())
 
-		var wg sync.WaitGroup
-  	numWorkers := runtime.GOMAXPROCS(0)
-		rowsPerWorker := size / numWorkers
-
-		for worker := 0; worker < numWorkers; worker++ {
-			wg.Add(1)
-			go func(worker int) {
-				defer wg.Done()
-				startRow := worker * rowsPerWorker
-				endRow := startRow + rowsPerWorker
-				if worker == numWorkers-1 {
-					endRow = size
-				}
-
-				for py := startRow; py < endRow; py++ {
-					for px := 0; px < size; px++ {
-						x := 2*float64(px)/float64(size) - 1
-						y := 2*float64(py)/float64(size) - 1
-						r, g, b := art.Eval(x, y)
-						r, g, b = normalize(r), normalize(g), normalize(b)
-						color := color.RGBA{
-							R: uint8(128 + r*127),
-							G: uint8(128 + g*127),
-							B: uint8(128 + b*127),
-							A: 255,
-						}
-						img.Set(px, py, color)
-					}
-				}
-			}(worker)
-		}
-
-		wg.Wait()
+		img = renderFrame(art, size, 0)
 
 		// Check variance
 		variance = calculateColorVariance(img, size)
@@ -235,18 +247,58 @@ func CreateImage(seed string, size int) image.Image {
 		fmt.Println("Warning: Generated image still has low variance.")
 	}
 
+	return img, art
+}
+
+// renderFrame evaluates art over the full size x size grid at a fixed time
+// t, splitting the rows across a worker per GOMAXPROCS the same way
+// CreateImage always has. It's factored out so animated renders can call it
+// once per frame instead of duplicating the worker-pool setup.
+func renderFrame(art ColorFunc, size int, t float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	var wg sync.WaitGroup
+	numWorkers := runtime.GOMAXPROCS(0)
+	rowsPerWorker := size / numWorkers
+
+	for worker := 0; worker < numWorkers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			startRow := worker * rowsPerWorker
+			endRow := startRow + rowsPerWorker
+			if worker == numWorkers-1 {
+				endRow = size
+			}
+
+			for py := startRow; py < endRow; py++ {
+				for px := 0; px < size; px++ {
+					x := 2*float64(px)/float64(size) - 1
+					y := 2*float64(py)/float64(size) - 1
+					r, g, b := art.Eval(x, y, t)
+					r, g, b = normalize(r), normalize(g), normalize(b)
+					c := color.RGBA{
+						R: uint8(128 + r*127),
+						G: uint8(128 + g*127),
+						B: uint8(128 + b*127),
+						A: 255,
+					}
+					img.Set(px, py, c)
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
 	return img
 }
 
-// Normalize ensures RGB values stay in the range [-1, 1].
+// Normalize ensures RGB values stay in the range [-1, 1]. It uses a tanh
+// rolloff rather than a hard clip: values near 0 pass through almost
+// unchanged, while values that would have clipped instead compress smoothly
+// toward ±1, so highly saturated regions fade out rather than posterizing.
 func normalize(value float64) float64 {
-	if value < -1 {
-		return -1
-	}
-	if value > 1 {
-		return 1
-	}
-	return value
+	return math.Tanh(value)
 }
 
 // Polar transforms Cartesian to polar coordinates.
@@ -277,7 +329,7 @@ func calculateColorVariance(img *image.RGBA, size int) float64 {
 	}
 
 	rMean, gMean, bMean := rTotal/pixelCount, gTotal/pixelCount, bTotal/pixelCount
-	return math.Sqrt((rSq/pixelCount-rMean*rMean) + (gSq/pixelCount-gMean*gMean) + (bSq/pixelCount-bMean*bMean))
+	return math.Sqrt((rSq/pixelCount - rMean*rMean) + (gSq/pixelCount - gMean*gMean) + (bSq/pixelCount - bMean*bMean))
 }
 
 // Hash the seed to get a consistent random seed.
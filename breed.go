@@ -0,0 +1,245 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Individual is one candidate in the breeding pool: a tree plus enough
+// lineage to show where it came from. Gen 0 individuals are freshly
+// Generate()'d and have no parents.
+type Individual struct {
+	ID        string
+	Tree      ColorFunc
+	ParentIDs []string
+	Gen       int
+}
+
+var (
+	breedMu    sync.Mutex
+	breedStore = map[string]*Individual{}
+	breedOrder []string
+	nextBreedN uint64
+)
+
+// maxBreedStoreSize bounds how many individuals breedStore holds at once.
+// Plain page views of /breed each mint a fresh population with no
+// selection, so without a cap the store would grow for the life of the
+// process; this keeps it FIFO-bounded to a few hundred breeding sessions'
+// worth instead.
+const maxBreedStoreSize = 5000
+
+// newIndividual allocates an ID, stores ind, and returns it. The store is a
+// plain in-memory map: breeding state only needs to survive across the
+// handful of requests in one breeding session, not restarts.
+func newIndividual(tree ColorFunc, gen int, parentIDs []string) *Individual {
+	id := strconv.FormatUint(atomic.AddUint64(&nextBreedN, 1), 36)
+	ind := &Individual{ID: id, Tree: tree, ParentIDs: parentIDs, Gen: gen}
+
+	breedMu.Lock()
+	breedStore[id] = ind
+	breedOrder = append(breedOrder, id)
+	for len(breedOrder) > maxBreedStoreSize {
+		delete(breedStore, breedOrder[0])
+		breedOrder = breedOrder[1:]
+	}
+	breedMu.Unlock()
+	return ind
+}
+
+func getIndividual(id string) (*Individual, bool) {
+	breedMu.Lock()
+	defer breedMu.Unlock()
+	ind, ok := breedStore[id]
+	return ind, ok
+}
+
+// nodeSlot is one parent->child edge in a tree: get reads the current
+// child, set replaces it. Walking a tree into a flat list of slots gives
+// crossover and mutation a uniform way to pick "some subtree" without a
+// type switch at every call site.
+type nodeSlot struct {
+	get func() ColorFunc
+	set func(ColorFunc)
+}
+
+func collectSlots(cf ColorFunc) []nodeSlot {
+	var slots []nodeSlot
+	var walk func(ColorFunc)
+	walk = func(cf ColorFunc) {
+		switch n := cf.(type) {
+		case *Sin:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *Well:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *FractalNoise:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *HSL:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *RGBToHSL:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *HSLToRGB:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *Hue:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *Saturate:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *Rotate:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.sub }, func(c ColorFunc) { n.sub = c }})
+			walk(n.sub)
+		case *Product:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.left }, func(c ColorFunc) { n.left = c }})
+			walk(n.left)
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.right }, func(c ColorFunc) { n.right = c }})
+			walk(n.right)
+		case *Mix:
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.left }, func(c ColorFunc) { n.left = c }})
+			walk(n.left)
+			slots = append(slots, nodeSlot{func() ColorFunc { return n.right }, func(c ColorFunc) { n.right = c }})
+			walk(n.right)
+		}
+	}
+	walk(cf)
+	return slots
+}
+
+// allNodes flattens a tree into every node it contains, root included.
+// Used to pick a random node to either graft from (crossover) or perturb
+// in place (mutation).
+func allNodes(cf ColorFunc) []ColorFunc {
+	nodes := []ColorFunc{cf}
+	switch n := cf.(type) {
+	case *Sin:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *Well:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *FractalNoise:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *HSL:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *RGBToHSL:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *HSLToRGB:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *Hue:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *Saturate:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *Rotate:
+		nodes = append(nodes, allNodes(n.sub)...)
+	case *Product:
+		nodes = append(nodes, allNodes(n.left)...)
+		nodes = append(nodes, allNodes(n.right)...)
+	case *Mix:
+		nodes = append(nodes, allNodes(n.left)...)
+		nodes = append(nodes, allNodes(n.right)...)
+	}
+	return nodes
+}
+
+// crossover grafts a randomly chosen subtree of b onto a random edge of a
+// clone of a. This is the standard simplification of two-parent crossover
+// for tree genomes: rather than swapping material both ways into two
+// children, it produces one child that's mostly a with a piece of b spliced
+// in, which is enough to mix traits across a breeding session.
+func crossover(a, b ColorFunc) ColorFunc {
+	child := cloneTree(a)
+	slots := collectSlots(child)
+	if len(slots) == 0 {
+		return child
+	}
+
+	donor := cloneTree(allNodes(b)[rand.Intn(len(allNodes(b)))])
+	slot := slots[rand.Intn(len(slots))]
+	slot.set(donor)
+	return child
+}
+
+// mutate clones cf and applies exactly one of: replacing a random subtree
+// with a freshly Generate()'d one, or perturbing a random node's own
+// parameters by a small Gaussian nudge.
+func mutate(cf ColorFunc) ColorFunc {
+	clone := cloneTree(cf)
+
+	if rand.Float64() < 0.5 {
+		slots := collectSlots(clone)
+		if len(slots) == 0 {
+			return Generate(2, 6)
+		}
+		slots[rand.Intn(len(slots))].set(Generate(1, 4))
+		return clone
+	}
+
+	nodes := allNodes(clone)
+	perturb(nodes[rand.Intn(len(nodes))])
+	return clone
+}
+
+// perturb nudges one node's tunable parameters by a small Gaussian. Only
+// nodes with continuous parameters are affected; structural nodes like
+// Product or Well have nothing to perturb.
+func perturb(n ColorFunc) {
+	gauss := func(scale float64) float64 { return rand.NormFloat64() * scale }
+
+	switch v := n.(type) {
+	case *Sin:
+		v.freq += gauss(0.3)
+		v.phase += gauss(0.3)
+	case *Mix:
+		v.w = clamp01(v.w + gauss(0.1))
+	case *FractalNoise:
+		v.scale += gauss(0.2)
+	case *Constant:
+		v.r = normalize(v.r + gauss(0.2))
+		v.g = normalize(v.g + gauss(0.2))
+		v.b = normalize(v.b + gauss(0.2))
+	case *Hue:
+		v.shift += gauss(15)
+	case *Saturate:
+		v.factor = math.Max(0, v.factor+gauss(0.2))
+	case *Rotate:
+		v.speed += gauss(0.3)
+	}
+}
+
+// nextGeneration produces count new individuals from the selected parents.
+// With no parents selected (e.g. the very first /breed call) it just
+// generates a fresh, unrelated population.
+func nextGeneration(selected []*Individual, count int) []*Individual {
+	children := make([]*Individual, count)
+
+	if len(selected) == 0 {
+		for i := range children {
+			children[i] = newIndividual(Generate(6, 20), 0, nil)
+		}
+		return children
+	}
+
+	for i := range children {
+		p1 := selected[rand.Intn(len(selected))]
+		p2 := selected[rand.Intn(len(selected))]
+
+		child := crossover(p1.Tree, p2.Tree)
+		if rand.Float64() < 0.5 {
+			child = mutate(child)
+		}
+
+		gen := p1.Gen
+		if p2.Gen > gen {
+			gen = p2.Gen
+		}
+		children[i] = newIndividual(child, gen+1, []string{p1.ID, p2.ID})
+	}
+	return children
+}
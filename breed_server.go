@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// breedPopulationSize is how many candidates are shown per generation.
+const breedPopulationSize = 9
+
+var breedTemplate = template.Must(template.New("breed").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Breed Random Art</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bulma@0.9.4/css/bulma.min.css">
+    <style>
+        body { text-align: center; margin-top: 50px; }
+        .candidate img { width: 220px; height: 220px; cursor: pointer; }
+        .candidate.selected img { outline: 4px solid hsl(171, 100%, 41%); }
+        .lineage { font-size: 0.75rem; word-break: break-all; }
+    </style>
+</head>
+<body>
+    <section class="section">
+        <div class="container">
+            <h1 class="title is-4">Breed Random Art</h1>
+            <p>Pick your favorites, then breed the next generation from them. Picking none starts a fresh population.</p>
+            <form method="POST" action="/breed">
+                <div class="columns is-multiline is-mobile">
+                    {{range .Individuals}}
+                    <div class="column is-one-third candidate">
+                        <label>
+                            <input type="checkbox" name="selected" value="{{.ID}}">
+                            <img src="/breed/render?id={{.ID}}&size=256" alt="individual {{.ID}}">
+                        </label>
+                        <p class="lineage">#{{.ID}} (gen {{.Gen}}){{if .ParentIDs}} from {{.ParentIDs}}{{end}}</p>
+                    </div>
+                    {{end}}
+                </div>
+                <button class="button is-primary" type="submit">Breed next generation</button>
+            </form>
+        </div>
+    </section>
+</body>
+</html>
+`))
+
+// breedViewData is what the breed template renders.
+type breedViewData struct {
+	Individuals []breedViewIndividual
+}
+
+// breedViewIndividual flattens Individual.ParentIDs into a display string
+// since html/template can't join a slice on its own.
+type breedViewIndividual struct {
+	ID        string
+	Gen       int
+	ParentIDs string
+}
+
+func toBreedView(individuals []*Individual) []breedViewIndividual {
+	views := make([]breedViewIndividual, len(individuals))
+	for i, ind := range individuals {
+		views[i] = breedViewIndividual{
+			ID:        ind.ID,
+			Gen:       ind.Gen,
+			ParentIDs: strings.Join(ind.ParentIDs, ", "),
+		}
+	}
+	return views
+}
+
+// breedHandler serves the breeding grid (GET) and advances a generation
+// from the favorites the user checked (POST).
+func breedHandler(w http.ResponseWriter, r *http.Request) {
+	var selected []*Individual
+
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form", http.StatusBadRequest)
+			return
+		}
+		for _, id := range r.Form["selected"] {
+			if ind, ok := getIndividual(id); ok {
+				selected = append(selected, ind)
+			}
+		}
+	}
+
+	individuals := nextGeneration(selected, breedPopulationSize)
+
+	w.Header().Set("Content-Type", "text/html")
+	breedTemplate.Execute(w, breedViewData{Individuals: toBreedView(individuals)})
+}
+
+// breedRenderImage renders one bred individual's tree to PNG, the same way
+// generateImage renders a seed's tree, but reading the tree from the
+// breeding store by ID instead of generating one from a seed.
+func breedRenderImage(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	ind, ok := getIndividual(id)
+	if !ok {
+		http.Error(w, "unknown individual", http.StatusNotFound)
+		return
+	}
+
+	size := 256
+	if s, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && s > 0 {
+		size = s
+	}
+	if size > maxRenderSize {
+		size = maxRenderSize
+	}
+
+	img := renderFrame(ind.Tree, size, 0)
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Filter is a post-processing step applied to the rendered RGBA image
+// before it's PNG-encoded. Filters are composed into an ordered chain and
+// run one after another, each taking the previous filter's output.
+type Filter interface {
+	Apply(img *image.RGBA) *image.RGBA
+}
+
+// parallelRows splits [0,height) across GOMAXPROCS workers, mirroring the
+// worker-pool split renderFrame uses for pixel rendering.
+func parallelRows(height int, fn func(y int)) {
+	var wg sync.WaitGroup
+	numWorkers := runtime.GOMAXPROCS(0)
+	if numWorkers > height {
+		numWorkers = height
+	}
+	rowsPerWorker := height / numWorkers
+
+	for worker := 0; worker < numWorkers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			startRow := worker * rowsPerWorker
+			endRow := startRow + rowsPerWorker
+			if worker == numWorkers-1 {
+				endRow = height
+			}
+			for y := startRow; y < endRow; y++ {
+				fn(y)
+			}
+		}(worker)
+	}
+	wg.Wait()
+}
+
+// clamp8 clips a float channel value into the valid uint8 range.
+func clamp8(v float64) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// gaussianKernel builds a normalized 1D Gaussian kernel sized from sigma.
+func gaussianKernel(sigma float64) []float64 {
+	if sigma <= 0 {
+		sigma = 0.01
+	}
+	radius := int(math.Ceil(3 * sigma))
+	if radius < 1 {
+		radius = 1
+	}
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := -radius; i <= radius; i++ {
+		v := math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		kernel[i+radius] = v
+		sum += v
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// convolveSeparable applies a 1D kernel horizontally then vertically, the
+// standard trick for turning an O(k^2) 2D convolution into two O(k) passes.
+// Edge pixels clamp to the border instead of wrapping or fading to black.
+func convolveSeparable(img *image.RGBA, kernel []float64) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	radius := len(kernel) / 2
+
+	horiz := image.NewRGBA(bounds)
+	parallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			var r, g, b, a float64
+			for k := -radius; k <= radius; k++ {
+				sx := clampInt(x+k, w)
+				c := img.RGBAAt(bounds.Min.X+sx, bounds.Min.Y+y)
+				weight := kernel[k+radius]
+				r += float64(c.R) * weight
+				g += float64(c.G) * weight
+				b += float64(c.B) * weight
+				a += float64(c.A) * weight
+			}
+			horiz.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA{clamp8(r), clamp8(g), clamp8(b), clamp8(a)})
+		}
+	})
+
+	out := image.NewRGBA(bounds)
+	parallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			var r, g, b, a float64
+			for k := -radius; k <= radius; k++ {
+				sy := clampInt(y+k, h)
+				c := horiz.RGBAAt(bounds.Min.X+x, bounds.Min.Y+sy)
+				weight := kernel[k+radius]
+				r += float64(c.R) * weight
+				g += float64(c.G) * weight
+				b += float64(c.B) * weight
+				a += float64(c.A) * weight
+			}
+			out.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA{clamp8(r), clamp8(g), clamp8(b), clamp8(a)})
+		}
+	})
+	return out
+}
+
+func clampInt(v, limit int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= limit {
+		return limit - 1
+	}
+	return v
+}
+
+// GaussianBlur softens an image by convolving it with a separable Gaussian
+// kernel sized from Sigma.
+type GaussianBlur struct {
+	Sigma float64
+}
+
+func (f GaussianBlur) Apply(img *image.RGBA) *image.RGBA {
+	return convolveSeparable(img, gaussianKernel(f.Sigma))
+}
+
+// UnsharpMask sharpens edges by subtracting a blurred copy of the image
+// from the original and adding the difference back in, scaled by Amount.
+type UnsharpMask struct {
+	Amount float64
+}
+
+func (f UnsharpMask) Apply(img *image.RGBA) *image.RGBA {
+	blurred := convolveSeparable(img, gaussianKernel(1.0))
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	parallelRows(bounds.Dy(), func(y int) {
+		for x := 0; x < bounds.Dx(); x++ {
+			px, py := bounds.Min.X+x, bounds.Min.Y+y
+			orig := img.RGBAAt(px, py)
+			blur := blurred.RGBAAt(px, py)
+			r := float64(orig.R) + f.Amount*(float64(orig.R)-float64(blur.R))
+			g := float64(orig.G) + f.Amount*(float64(orig.G)-float64(blur.G))
+			b := float64(orig.B) + f.Amount*(float64(orig.B)-float64(blur.B))
+			out.SetRGBA(px, py, color.RGBA{clamp8(r), clamp8(g), clamp8(b), orig.A})
+		}
+	})
+	return out
+}
+
+// EdgeDetect highlights edges with the Sobel operator. Sobel is separable:
+// Gx is a horizontal [-1 0 1] derivative smoothed vertically by [1 2 1],
+// and Gy is its transpose. The gradient magnitude becomes grayscale output.
+type EdgeDetect struct{}
+
+func (f EdgeDetect) Apply(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	gray := make([]float64, w*h)
+	parallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			gray[y*w+x] = 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+		}
+	})
+
+	at := func(x, y int) float64 {
+		return gray[clampInt(y, h)*w+clampInt(x, w)]
+	}
+
+	out := image.NewRGBA(bounds)
+	parallelRows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			gx := (at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1)) - (at(x-1, y-1) + 2*at(x-1, y) + at(x-1, y+1))
+			gy := (at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)) - (at(x-1, y-1) + 2*at(x, y-1) + at(x+1, y-1))
+			v := clamp8(math.Sqrt(gx*gx + gy*gy))
+			out.SetRGBA(bounds.Min.X+x, bounds.Min.Y+y, color.RGBA{v, v, v, 255})
+		}
+	})
+	return out
+}
+
+// Contrast scales each channel's distance from mid-gray by Factor (>1
+// increases contrast, <1 flattens it) — the simplest form of tone mapping.
+type Contrast struct {
+	Factor float64
+}
+
+func (f Contrast) Apply(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	adjust := func(v uint8) uint8 {
+		return clamp8((float64(v)-128)*f.Factor + 128)
+	}
+	parallelRows(bounds.Dy(), func(y int) {
+		for x := 0; x < bounds.Dx(); x++ {
+			px, py := bounds.Min.X+x, bounds.Min.Y+y
+			c := img.RGBAAt(px, py)
+			out.SetRGBA(px, py, color.RGBA{adjust(c.R), adjust(c.G), adjust(c.B), c.A})
+		}
+	})
+	return out
+}
+
+// FilterSpec is the JSON-body form of a filter chain entry, e.g.
+// {"name": "blur", "value": 2}.
+type FilterSpec struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Parameter bounds for filters built from untrusted request input.
+// maxSigma keeps gaussianKernel's radius (ceil(3*sigma)) from growing large
+// enough to make convolveSeparable's O(w*h*radius) cost unbounded.
+const (
+	minSigma  = 0.0
+	maxSigma  = 20.0
+	minAmount = 0.0
+	maxAmount = 5.0
+	minFactor = 0.0
+	maxFactor = 5.0
+)
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// BuildFilters turns an ordered list of specs into the concrete Filter
+// chain. Unknown names are skipped rather than erroring, so a typo
+// degrades gracefully instead of failing the whole render. Numeric
+// parameters are clamped to a bounded range, since they come straight
+// from an unauthenticated request and size the work each filter does.
+func BuildFilters(specs []FilterSpec) []Filter {
+	var filters []Filter
+	for _, spec := range specs {
+		switch spec.Name {
+		case "blur":
+			filters = append(filters, GaussianBlur{Sigma: clampFloat(spec.Value, minSigma, maxSigma)})
+		case "sharpen":
+			filters = append(filters, UnsharpMask{Amount: clampFloat(spec.Value, minAmount, maxAmount)})
+		case "edge":
+			filters = append(filters, EdgeDetect{})
+		case "contrast":
+			filters = append(filters, Contrast{Factor: clampFloat(spec.Value, minFactor, maxFactor)})
+		}
+	}
+	return filters
+}
+
+// ParseFilterChain parses the compact query-string form used by ?fx=, e.g.
+// "blur:2,sharpen:0.5,contrast:1.2".
+func ParseFilterChain(fx string) []Filter {
+	var specs []FilterSpec
+	for _, part := range strings.Split(fx, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, ":")
+		value, _ := strconv.ParseFloat(arg, 64)
+		specs = append(specs, FilterSpec{Name: name, Value: value})
+	}
+	return BuildFilters(specs)
+}
+
+// ApplyFilters runs img through each filter in order, returning the result.
+func ApplyFilters(img *image.RGBA, filters []Filter) *image.RGBA {
+	for _, f := range filters {
+		img = f.Apply(img)
+	}
+	return img
+}
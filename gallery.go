@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// galleryDir is where the gallery's index lives on disk. The app otherwise
+// has no persistence, so a JSON file is enough without pulling in a
+// database driver.
+const galleryDir = "gallery"
+const galleryIndexPath = galleryDir + "/index.json"
+
+// GalleryEntry is one saved render: enough to show a permalink and
+// rerender at a different size without re-running Generate, which would
+// drift if Generate's weights ever change.
+type GalleryEntry struct {
+	ID        string    `json:"id"`
+	Seed      string    `json:"seed"`
+	Size      int       `json:"size"`
+	Tree      string    `json:"tree"`
+	CreatedAt time.Time `json:"created_at"`
+	PHash     uint64    `json:"phash"`
+}
+
+var (
+	galleryMu    sync.Mutex
+	galleryIndex []GalleryEntry
+	galleryNextN uint64
+)
+
+func init() {
+	loadGalleryIndex()
+}
+
+// loadGalleryIndex reads the on-disk index, if any, into memory. A missing
+// file just means the gallery hasn't saved anything yet.
+func loadGalleryIndex() {
+	data, err := os.ReadFile(galleryIndexPath)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &galleryIndex); err != nil {
+		return
+	}
+	for _, e := range galleryIndex {
+		if n, err := strconv.ParseUint(e.ID, 10, 64); err == nil && n >= galleryNextN {
+			galleryNextN = n + 1
+		}
+	}
+}
+
+// saveGalleryIndex persists the in-memory index, overwriting the file.
+// Callers must hold galleryMu.
+func saveGalleryIndex() error {
+	if err := os.MkdirAll(galleryDir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(galleryIndex, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(galleryIndexPath, data, 0o644)
+}
+
+// saveToGallery records a rendered tree's seed, size, source, timestamp,
+// and perceptual hash, and returns the new entry.
+func saveToGallery(seed string, size int, art ColorFunc, img image.Image) (GalleryEntry, error) {
+	galleryMu.Lock()
+	defer galleryMu.Unlock()
+
+	entry := GalleryEntry{
+		ID:        strconv.FormatUint(galleryNextN, 10),
+		Seed:      seed,
+		Size:      size,
+		Tree:      art.String(),
+		CreatedAt: time.Now(),
+		PHash:     averageHash(img),
+	}
+	galleryNextN++
+
+	galleryIndex = append(galleryIndex, entry)
+	if err := saveGalleryIndex(); err != nil {
+		return GalleryEntry{}, err
+	}
+	return entry, nil
+}
+
+// getGalleryEntry looks up a saved entry by ID.
+func getGalleryEntry(id string) (GalleryEntry, bool) {
+	galleryMu.Lock()
+	defer galleryMu.Unlock()
+	for _, e := range galleryIndex {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return GalleryEntry{}, false
+}
+
+// listGallery returns entries newest-first, paginated by a 1-indexed page
+// number, along with the total entry count.
+func listGallery(page, pageSize int) ([]GalleryEntry, int) {
+	galleryMu.Lock()
+	defer galleryMu.Unlock()
+
+	total := len(galleryIndex)
+	ordered := make([]GalleryEntry, total)
+	for i, e := range galleryIndex {
+		ordered[total-1-i] = e
+	}
+
+	start := (page - 1) * pageSize
+	if start < 0 || start >= total {
+		return nil, total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return ordered[start:end], total
+}
+
+// toRGBA converts any image.Image to *image.RGBA, which is what the
+// Lanczos resizer and hash operate on.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+	return out
+}
+
+// averageHash computes a simple 64-bit perceptual hash: resize to 8x8
+// grayscale with the Lanczos resizer, then set each bit based on whether
+// that pixel is brighter than the thumbnail's average brightness. Visually
+// similar images hash to a small Hamming distance, which is enough to spot
+// near-duplicate seeds without a heavier DCT-based pHash.
+func averageHash(img image.Image) uint64 {
+	const n = 8
+	thumb := lanczosResize(toRGBA(img), n, n)
+
+	gray := make([]float64, n*n)
+	var sum float64
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			c := thumb.RGBAAt(x, y)
+			g := 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+			gray[y*n+x] = g
+			sum += g
+		}
+	}
+	avg := sum / float64(n*n)
+
+	var hash uint64
+	for i, g := range gray {
+		if g > avg {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// lanczosA is the Lanczos kernel's support radius (Lanczos-3 is the usual
+// default: sharp enough for thumbnails without excessive ringing).
+const lanczosA = 3
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	piX := math.Pi * x
+	return math.Sin(piX) / piX
+}
+
+func lanczosKernel(x float64) float64 {
+	if x <= -lanczosA || x >= lanczosA {
+		return 0
+	}
+	return sinc(x) * sinc(x/lanczosA)
+}
+
+// lanczosResize resizes src to dstW x dstH with a separable Lanczos-3
+// resampler (horizontal pass, then vertical), the standard approach for
+// downscaling without the blockiness nearest-neighbor leaves in thumbnails.
+func lanczosResize(src *image.RGBA, dstW, dstH int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	horiz := image.NewRGBA(image.Rect(0, 0, dstW, srcH))
+	scaleX := float64(srcW) / float64(dstW)
+	parallelRows(srcH, func(y int) {
+		for dx := 0; dx < dstW; dx++ {
+			srcX := (float64(dx)+0.5)*scaleX - 0.5
+			r, g, b, a := lanczosSample1D(src, srcX, y, srcW, true)
+			horiz.SetRGBA(dx, y, color.RGBA{clamp8(r), clamp8(g), clamp8(b), clamp8(a)})
+		}
+	})
+
+	out := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	scaleY := float64(srcH) / float64(dstH)
+	parallelRows(dstH, func(dy int) {
+		srcY := (float64(dy)+0.5)*scaleY - 0.5
+		for x := 0; x < dstW; x++ {
+			r, g, b, a := lanczosSample1D(horiz, srcY, x, srcH, false)
+			out.SetRGBA(x, dy, color.RGBA{clamp8(r), clamp8(g), clamp8(b), clamp8(a)})
+		}
+	})
+	return out
+}
+
+// lanczosSample1D samples img along one axis (horizontal if horizontal,
+// else vertical) around center, weighting by the Lanczos kernel and
+// clamping out-of-range taps to the image edge.
+func lanczosSample1D(img *image.RGBA, center float64, fixed, axisLen int, horizontal bool) (r, g, b, a float64) {
+	lo := int(math.Floor(center)) - lanczosA + 1
+	hi := int(math.Floor(center)) + lanczosA
+
+	var wsum float64
+	for i := lo; i <= hi; i++ {
+		weight := lanczosKernel(center - float64(i))
+		if weight == 0 {
+			continue
+		}
+		ci := clampInt(i, axisLen)
+
+		var c color.RGBA
+		if horizontal {
+			c = img.RGBAAt(ci, fixed)
+		} else {
+			c = img.RGBAAt(fixed, ci)
+		}
+		r += float64(c.R) * weight
+		g += float64(c.G) * weight
+		b += float64(c.B) * weight
+		a += float64(c.A) * weight
+		wsum += weight
+	}
+	if wsum != 0 {
+		r /= wsum
+		g /= wsum
+		b /= wsum
+		a /= wsum
+	}
+	return
+}
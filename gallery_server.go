@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	galleryPageSize  = 24
+	defaultThumbSize = 160
+	thumbRenderSize  = 512 // base resolution rendered before Lanczos-downsizing to the thumbnail size
+)
+
+var galleryTemplate = template.Must(template.New("gallery").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Gallery</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bulma@0.9.4/css/bulma.min.css">
+    <style>
+        body { text-align: center; margin-top: 50px; }
+        .thumb img { width: 160px; height: 160px; }
+    </style>
+</head>
+<body>
+    <section class="section">
+        <div class="container">
+            <h1 class="title is-4">Gallery</h1>
+            <div class="columns is-multiline is-mobile">
+                {{range .Entries}}
+                <div class="column is-narrow thumb">
+                    <a href="/art/{{.ID}}">
+                        <img src="/art/{{.ID}}/thumb" alt="art {{.ID}}">
+                    </a>
+                    <p>#{{.ID}}</p>
+                </div>
+                {{end}}
+            </div>
+            <nav class="pagination is-centered" role="navigation">
+                {{if .HasPrev}}<a class="pagination-previous" href="/gallery?page={{.PrevPage}}">Previous</a>{{end}}
+                {{if .HasNext}}<a class="pagination-next" href="/gallery?page={{.NextPage}}">Next</a>{{end}}
+            </nav>
+        </div>
+    </section>
+</body>
+</html>
+`))
+
+var artTemplate = template.Must(template.New("art").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Art #{{.Entry.ID}}</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bulma@0.9.4/css/bulma.min.css">
+    <style>
+        body { text-align: center; margin-top: 50px; }
+        img { width: 512px; height: 512px; margin: auto; }
+        .tree { font-size: 0.75rem; word-break: break-all; text-align: left; }
+    </style>
+</head>
+<body>
+    <section class="section">
+        <div class="container">
+            <h1 class="title is-4">Art #{{.Entry.ID}}</h1>
+            <img src="/art/{{.Entry.ID}}/rerender?size=512" alt="art {{.Entry.ID}}">
+            <p>Seed: {{.Entry.Seed}} &middot; Size: {{.Entry.Size}} &middot; Saved: {{.Entry.CreatedAt}}</p>
+            <form action="/art/{{.Entry.ID}}/rerender" method="GET">
+                <div class="field has-addons is-justify-content-center">
+                    <div class="control">
+                        <input class="input" type="number" name="size" value="{{.Entry.Size}}">
+                    </div>
+                    <div class="control">
+                        <button class="button is-primary" type="submit">Rerender at size</button>
+                    </div>
+                </div>
+            </form>
+            <pre class="tree">{{.Entry.Tree}}</pre>
+        </div>
+    </section>
+</body>
+</html>
+`))
+
+// galleryViewData is what the gallery template renders.
+type galleryViewData struct {
+	Entries  []GalleryEntry
+	HasPrev  bool
+	PrevPage int
+	HasNext  bool
+	NextPage int
+}
+
+// galleryHandler serves a paginated grid of gallery thumbnails.
+func galleryHandler(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	entries, total := listGallery(page, galleryPageSize)
+
+	data := galleryViewData{
+		Entries:  entries,
+		HasPrev:  page > 1,
+		PrevPage: page - 1,
+		HasNext:  page*galleryPageSize < total,
+		NextPage: page + 1,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	galleryTemplate.Execute(w, data)
+}
+
+// artHandler routes everything under /art/{id}, including its /rerender
+// and /thumb sub-resources, since this app otherwise has no path-parameter
+// router.
+func artHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/art/")
+	parts := strings.SplitN(path, "/", 2)
+	id := parts[0]
+	if id == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	entry, ok := getGalleryEntry(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		w.Header().Set("Content-Type", "text/html")
+		artTemplate.Execute(w, struct{ Entry GalleryEntry }{entry})
+		return
+	}
+
+	switch parts[1] {
+	case "rerender":
+		artRerender(w, r, entry)
+	case "thumb":
+		artThumb(w, r, entry)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// artRerender regenerates a permalinked render from its stored expression
+// tree rather than its seed, so the result stays stable across a change to
+// Generate's weights even when asked for a different size.
+func artRerender(w http.ResponseWriter, r *http.Request, entry GalleryEntry) {
+	size := entry.Size
+	if s, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && s > 0 {
+		size = s
+	}
+	if size > maxRenderSize {
+		size = maxRenderSize
+	}
+
+	art, err := ParseTree(entry.Tree)
+	if err != nil {
+		http.Error(w, "stored tree could not be parsed", http.StatusInternalServerError)
+		return
+	}
+
+	img := renderFrame(art, size, 0)
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// artThumb renders entry's tree at a modest base resolution and
+// Lanczos-downsamples it to the requested thumbnail size, which looks
+// far better than nearest-neighbor at the small sizes the gallery grid uses.
+func artThumb(w http.ResponseWriter, r *http.Request, entry GalleryEntry) {
+	size := defaultThumbSize
+	if s, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && s > 0 {
+		size = s
+	}
+	if size > maxRenderSize {
+		size = maxRenderSize
+	}
+
+	art, err := ParseTree(entry.Tree)
+	if err != nil {
+		http.Error(w, "stored tree could not be parsed", http.StatusInternalServerError)
+		return
+	}
+
+	base := renderFrame(art, thumbRenderSize, 0)
+	thumb := lanczosResize(base, size, size)
+
+	var buf bytes.Buffer
+	png.Encode(&buf, thumb)
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Nodes below let an expression subtree be interpreted in HSL space instead
+// of straight RGB. RGBToHSL/HSLToRGB convert between RGB-space and
+// HSL-space channel triples (both still packed into the usual [-1,1]
+// ColorFunc range), and Hue/Saturate/Rotate operate on an HSL-space triple
+// in between. HSL is the shorthand for the common case of interpreting a
+// subtree's output directly as hue/sat/lightness and converting straight
+// to RGB.
+
+// clamp01 clips v into [0,1].
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// packHSL encodes hue (degrees, [0,360)), saturation and lightness
+// ([0,1]) into the [-1,1] triple every ColorFunc passes around.
+func packHSL(h, s, l float64) (float64, float64, float64) {
+	return h/180 - 1, s*2 - 1, l*2 - 1
+}
+
+// unpackHSL is packHSL's inverse.
+func unpackHSL(ph, ps, pl float64) (h, s, l float64) {
+	h = math.Mod((ph+1)*180, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clamp01((ps + 1) / 2)
+	l = clamp01((pl + 1) / 2)
+	return
+}
+
+// hslToRGB converts h (degrees, [0,360)), s and l ([0,1]) to r, g, b in
+// [0,1] using the standard piecewise formula.
+func hslToRGB(h, s, l float64) (r, g, b float64) {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r1, g1, b1 float64
+	switch {
+	case hp < 1:
+		r1, g1, b1 = c, x, 0
+	case hp < 2:
+		r1, g1, b1 = x, c, 0
+	case hp < 3:
+		r1, g1, b1 = 0, c, x
+	case hp < 4:
+		r1, g1, b1 = 0, x, c
+	case hp < 5:
+		r1, g1, b1 = x, 0, c
+	default:
+		r1, g1, b1 = c, 0, x
+	}
+
+	m := l - c/2
+	return r1 + m, g1 + m, b1 + m
+}
+
+// rgbToHSL converts r, g, b in [0,1] to h (degrees, [0,360)), s and l
+// ([0,1]).
+func rgbToHSL(r, g, b float64) (h, s, l float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return
+}
+
+// RGBToHSL reinterprets sub's RGB-space output as HSL-space, so downstream
+// nodes like Hue, Saturate, and Rotate can operate on hue/sat/lightness
+// directly.
+type RGBToHSL struct {
+	sub ColorFunc
+}
+
+func (n *RGBToHSL) Eval(x, y, t float64) (float64, float64, float64) {
+	r, g, b := n.sub.Eval(x, y, t)
+	h, s, l := rgbToHSL(clamp01((r+1)/2), clamp01((g+1)/2), clamp01((b+1)/2))
+	return packHSL(h, s, l)
+}
+
+func (n *RGBToHSL) String() string {
+	return fmt.Sprintf("RGBToHSL(sub=%s)", n.sub.String())
+}
+
+// HSLToRGB converts an HSL-space subtree back to RGB-space so it can be
+// composed with ordinary nodes again.
+type HSLToRGB struct {
+	sub ColorFunc
+}
+
+func (n *HSLToRGB) Eval(x, y, t float64) (float64, float64, float64) {
+	ph, ps, pl := n.sub.Eval(x, y, t)
+	h, s, l := unpackHSL(ph, ps, pl)
+	r, g, b := hslToRGB(h, s, l)
+	return r*2 - 1, g*2 - 1, b*2 - 1
+}
+
+func (n *HSLToRGB) String() string {
+	return fmt.Sprintf("HSLToRGB(sub=%s)", n.sub.String())
+}
+
+// HSL interprets sub's output directly as packed hue/sat/lightness and
+// converts it to RGB, the shorthand for HSLToRGB{sub}. Building expressions
+// this way keeps generated palettes visually coherent, since a shared
+// lightness/saturation channel ties hues together instead of each channel
+// drifting independently the way raw RGB subtrees do.
+type HSL struct {
+	sub ColorFunc
+}
+
+func (n *HSL) Eval(x, y, t float64) (float64, float64, float64) {
+	h, s, l := unpackHSL(n.sub.Eval(x, y, t))
+	r, g, b := hslToRGB(h, s, l)
+	return r*2 - 1, g*2 - 1, b*2 - 1
+}
+
+func (n *HSL) String() string {
+	return fmt.Sprintf("HSL(sub=%s)", n.sub.String())
+}
+
+// Hue shifts an HSL-space subtree's hue by a fixed number of degrees,
+// wrapping around the color wheel.
+type Hue struct {
+	sub   ColorFunc
+	shift float64
+}
+
+func (n *Hue) Eval(x, y, t float64) (float64, float64, float64) {
+	h, s, l := unpackHSL(n.sub.Eval(x, y, t))
+	h = math.Mod(h+n.shift, 360)
+	if h < 0 {
+		h += 360
+	}
+	return packHSL(h, s, l)
+}
+
+func (n *Hue) String() string {
+	return fmt.Sprintf("Hue(shift=%.2f, sub=%s)", n.shift, n.sub.String())
+}
+
+// Saturate scales an HSL-space subtree's saturation by factor.
+type Saturate struct {
+	sub    ColorFunc
+	factor float64
+}
+
+func (n *Saturate) Eval(x, y, t float64) (float64, float64, float64) {
+	h, s, l := unpackHSL(n.sub.Eval(x, y, t))
+	return packHSL(h, clamp01(s*n.factor), l)
+}
+
+func (n *Saturate) String() string {
+	return fmt.Sprintf("Saturate(factor=%.2f, sub=%s)", n.factor, n.sub.String())
+}
+
+// Rotate spins an HSL-space subtree's hue around the color wheel over
+// time, at the given speed in degrees per unit t.
+type Rotate struct {
+	sub   ColorFunc
+	speed float64
+}
+
+func (n *Rotate) Eval(x, y, t float64) (float64, float64, float64) {
+	h, s, l := unpackHSL(n.sub.Eval(x, y, t))
+	h = math.Mod(h+n.speed*t, 360)
+	if h < 0 {
+		h += 360
+	}
+	return packHSL(h, s, l)
+}
+
+func (n *Rotate) String() string {
+	return fmt.Sprintf("Rotate(speed=%.2f, sub=%s)", n.speed, n.sub.String())
+}
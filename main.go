@@ -8,6 +8,13 @@ import (
 func main() {
 	http.HandleFunc("/", serveHome)
 	http.HandleFunc("/generate", generateImage)
+	http.HandleFunc("/animate", animateImage)
+	http.HandleFunc("/breed", breedHandler)
+	http.HandleFunc("/breed/render", breedRenderImage)
+	http.HandleFunc("/tiles", serveTiles)
+	http.HandleFunc("/tiles/ws", tilesWS)
+	http.HandleFunc("/gallery", galleryHandler)
+	http.HandleFunc("/art/", artHandler)
 
 	fmt.Println("Server is running at http://localhost:8080")
 	http.ListenAndServe(":8080", nil)
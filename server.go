@@ -2,12 +2,31 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"html/template"
+	"image"
 	"image/png"
+	"math/rand"
 	"net/http"
 	"strconv"
 )
 
+// Animation query defaults. fps and loop are user-tunable via query params;
+// frames controls how many samples of t in [0, 2π) get rendered.
+const (
+	defaultFrames = 30
+	defaultFPS    = 15
+	maxFrames     = 240
+)
+
+// maxRenderSize bounds any user-supplied render size (animate, breed,
+// gallery rerenders/thumbnails). Without it, an unauthenticated request
+// could demand an arbitrarily large renderFrame buffer — at maxFrames
+// frames, an uncapped size would multiply into gigabytes of RGBA data
+// and minutes of CPU for one GET.
+const maxRenderSize = 2048
+
 // Templates
 var templates = template.Must(template.New("main").Parse(`
 <!DOCTYPE html>
@@ -120,7 +139,22 @@ func generateImage(w http.ResponseWriter, r *http.Request) {
 		size = s
 	}
 
-	img := CreateImage(seed, size)
+	img, art := CreateImage(seed, size)
+
+	// Save the unfiltered render: the gallery's permalink and thumbnail
+	// always rerender from the stored tree alone, so hashing a
+	// filtered img here would make PHash describe an image the gallery
+	// can never reproduce.
+	if _, err := saveToGallery(seed, size, art, img); err != nil {
+		fmt.Printf("gallery: failed to save seed %q: %v\n", seed, err)
+	}
+
+	filters := filtersFromRequest(r)
+	if len(filters) > 0 {
+		if rgba, ok := img.(*image.RGBA); ok {
+			img = ApplyFilters(rgba, filters)
+		}
+	}
 
 	var buf bytes.Buffer
 	png.Encode(&buf, img)
@@ -128,3 +162,74 @@ func generateImage(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "image/png")
 	w.Write(buf.Bytes())
 }
+
+// filtersFromRequest builds the post-processing filter chain for a request.
+// A JSON body of FilterSpecs takes precedence over the compact "?fx="
+// query form, so callers can use whichever is more convenient.
+func filtersFromRequest(r *http.Request) []Filter {
+	if r.Method == http.MethodPost && r.Body != nil {
+		var specs []FilterSpec
+		if err := json.NewDecoder(r.Body).Decode(&specs); err == nil && len(specs) > 0 {
+			return BuildFilters(specs)
+		}
+	}
+	if fx := r.URL.Query().Get("fx"); fx != "" {
+		return ParseFilterChain(fx)
+	}
+	return nil
+}
+
+// animateImage renders an animated GIF by sweeping the expression tree's
+// time parameter t from 0 to 2π. We only emit GIF: the standard library has
+// no APNG encoder and pulling one in is more than this endpoint needs.
+func animateImage(w http.ResponseWriter, r *http.Request) {
+	seed := r.URL.Query().Get("seed")
+	if seed == "" {
+		http.Error(w, "Seed is required", http.StatusBadRequest)
+		return
+	}
+
+	size := 512
+	if s, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && s > 0 {
+		size = s
+	}
+	if size > maxRenderSize {
+		size = maxRenderSize
+	}
+
+	frames := defaultFrames
+	if f, err := strconv.Atoi(r.URL.Query().Get("frames")); err == nil && f > 0 {
+		frames = f
+	}
+	if frames > maxFrames {
+		frames = maxFrames
+	}
+
+	fps := defaultFPS
+	if f, err := strconv.Atoi(r.URL.Query().Get("fps")); err == nil && f > 0 {
+		fps = f
+	}
+
+	loop := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("loop")); err == nil && l >= 0 {
+		loop = l
+	}
+
+	rand.Seed(int64(hash(seed)))
+	art := Generate(10, 30)
+
+	imgs := animationFrames(art, size, frames)
+
+	var buf bytes.Buffer
+	delay := 100 / fps
+	if delay < 1 {
+		delay = 1
+	}
+	if err := encodeGIF(&buf, imgs, delay, loop); err != nil {
+		http.Error(w, "failed to encode animation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"runtime"
+	"sync"
+)
+
+// tileJob describes one tile of a larger render: a rectangle at (X, Y)
+// sized W x H within the full image.
+type tileJob struct {
+	X, Y, W, H int
+}
+
+// tileResult is one rendered tile, either the coarse dithered Preview pass
+// or the Final full-resolution pass, already PNG-encoded so the WebSocket
+// writer goroutine can ship it straight to the client.
+type tileResult struct {
+	Job   tileJob
+	Phase string // "preview" or "final"
+	PNG   []byte
+}
+
+// genTiles splits a size x size image into tileSize x tileSize tiles,
+// clipping the last row/column to fit.
+func genTiles(size, tileSize int) []tileJob {
+	var jobs []tileJob
+	for y := 0; y < size; y += tileSize {
+		h := tileSize
+		if y+h > size {
+			h = size - y
+		}
+		for x := 0; x < size; x += tileSize {
+			w := tileSize
+			if x+w > size {
+				w = size - x
+			}
+			jobs = append(jobs, tileJob{X: x, Y: y, W: w, H: h})
+		}
+	}
+	return jobs
+}
+
+// previewBlock is the edge length of the blocks the coarse preview pass
+// fills from a single sample, i.e. it evaluates roughly every 8th pixel.
+const previewBlock = 8
+
+// bayer8 is the standard 8x8 ordered-dither threshold matrix. Within each
+// previewBlock x previewBlock block, the position holding the lowest value
+// is the one sampled for the coarse preview, which spreads the handful of
+// real samples across the block instead of clumping them in a corner.
+var bayer8 = [8][8]int{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// evalPixel maps a pixel in the full size x size image to the [-1,1]
+// coordinate space CreateImage/renderFrame use, and returns its 0-255 RGBA.
+func evalPixel(art ColorFunc, size, px, py int, t float64) color.RGBA {
+	x := 2*float64(px)/float64(size) - 1
+	y := 2*float64(py)/float64(size) - 1
+	r, g, b := art.Eval(x, y, t)
+	r, g, b = normalize(r), normalize(g), normalize(b)
+	return color.RGBA{
+		R: uint8(128 + r*127),
+		G: uint8(128 + g*127),
+		B: uint8(128 + b*127),
+		A: 255,
+	}
+}
+
+// renderTilePreview renders a coarse version of job by sampling one pixel
+// per previewBlock block (chosen via bayer8) and flat-filling the block
+// with it, giving a near-instant low-detail preview before the full pass.
+func renderTilePreview(art ColorFunc, size int, job tileJob, t float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, job.W, job.H))
+
+	for by := 0; by < job.H; by += previewBlock {
+		for bx := 0; bx < job.W; bx += previewBlock {
+			sampleOX, sampleOY, lowest := 0, 0, 65
+			for oy := 0; oy < previewBlock && by+oy < job.H; oy++ {
+				for ox := 0; ox < previewBlock && bx+ox < job.W; ox++ {
+					if v := bayer8[oy][ox]; v < lowest {
+						lowest, sampleOX, sampleOY = v, ox, oy
+					}
+				}
+			}
+
+			c := evalPixel(art, size, job.X+bx+sampleOX, job.Y+by+sampleOY, t)
+			for oy := 0; oy < previewBlock && by+oy < job.H; oy++ {
+				for ox := 0; ox < previewBlock && bx+ox < job.W; ox++ {
+					img.SetRGBA(bx+ox, by+oy, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// renderTileFull renders job at full resolution, one Eval per pixel.
+func renderTileFull(art ColorFunc, size int, job tileJob, t float64) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, job.W, job.H))
+	for dy := 0; dy < job.H; dy++ {
+		for dx := 0; dx < job.W; dx++ {
+			img.SetRGBA(dx, dy, evalPixel(art, size, job.X+dx, job.Y+dy, t))
+		}
+	}
+	return img
+}
+
+func encodeTilePNG(img *image.RGBA) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// scheduleTiles hands tiles out from a channel to GOMAXPROCS workers,
+// replacing the row-strip split CreateImage uses: a slow, sample-heavy
+// tile no longer stalls an entire horizontal band of the image. Each
+// worker emits a preview result for a tile before its final result, so the
+// client can compose a coarse image while detail is still arriving.
+// Closing cancel stops dispatching new tiles to already-idle workers;
+// tiles already in flight still finish and get emitted.
+func scheduleTiles(art ColorFunc, size, tileSize int, t float64, cancel <-chan struct{}) <-chan tileResult {
+	jobs := genTiles(size, tileSize)
+	jobCh := make(chan tileJob, len(jobs))
+	for _, job := range jobs {
+		jobCh <- job
+	}
+	close(jobCh)
+
+	resultCh := make(chan tileResult, len(jobs)*2)
+	numWorkers := runtime.GOMAXPROCS(0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				select {
+				case <-cancel:
+					return
+				default:
+				}
+
+				preview := renderTilePreview(art, size, job, t)
+				resultCh <- tileResult{Job: job, Phase: "preview", PNG: encodeTilePNG(preview)}
+
+				full := renderTileFull(art, size, job, t)
+				resultCh <- tileResult{Job: job, Phase: "final", PNG: encodeTilePNG(full)}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	return resultCh
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"html/template"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// upgrader has CheckOrigin always allow: this app has no cross-site
+// credential state to protect, same as its other endpoints taking
+// unauthenticated GET/POST requests.
+var tileUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// tileMessage is one WebSocket frame sent to the browser. PNG is
+// marshaled as base64 by encoding/json's default []byte handling.
+type tileMessage struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	W     int    `json:"w"`
+	H     int    `json:"h"`
+	Phase string `json:"phase"`
+	PNG   []byte `json:"png"`
+}
+
+// Tile query bounds. tileSize is clamped well away from 1 (which would
+// otherwise turn a large size into millions of tile jobs) and size is
+// capped the same way /animate caps frames, since both come straight
+// from an unauthenticated query string.
+const (
+	defaultTileSize = 64
+	minTileSize     = 16
+	maxTileSize     = 512
+	maxTiledSize    = 4096
+)
+
+// tilesWS streams a tiled, progressively-refined render over a WebSocket.
+// Any message from the client (including the close frame sent when the
+// page navigates away) cancels rendering of tiles not yet started.
+func tilesWS(w http.ResponseWriter, r *http.Request) {
+	seed := r.URL.Query().Get("seed")
+	if seed == "" {
+		http.Error(w, "Seed is required", http.StatusBadRequest)
+		return
+	}
+
+	size := 1024
+	if s, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && s > 0 {
+		size = s
+	}
+	if size > maxTiledSize {
+		size = maxTiledSize
+	}
+	tileSize := defaultTileSize
+	if ts, err := strconv.Atoi(r.URL.Query().Get("tileSize")); err == nil && ts > 0 {
+		tileSize = ts
+	}
+	if tileSize < minTileSize {
+		tileSize = minTileSize
+	}
+	if tileSize > maxTileSize {
+		tileSize = maxTileSize
+	}
+
+	conn, err := tileUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	rand.Seed(int64(hash(seed)))
+	art := Generate(10, 30)
+
+	cancel := make(chan struct{})
+	var cancelOnce sync.Once
+	stop := func() { cancelOnce.Do(func() { close(cancel) }) }
+
+	go func() {
+		// The only thing we expect from the client is a close frame
+		// (e.g. the user cancelled or navigated away); any read error,
+		// including that close, means stop dispatching new tiles.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				stop()
+				return
+			}
+		}
+	}()
+
+	for res := range scheduleTiles(art, size, tileSize, 0, cancel) {
+		msg := tileMessage{X: res.Job.X, Y: res.Job.Y, W: res.Job.W, H: res.Job.H, Phase: res.Phase, PNG: res.PNG}
+		if err := conn.WriteJSON(msg); err != nil {
+			stop()
+			break
+		}
+	}
+}
+
+var tilesTemplate = template.Must(template.New("tiles").Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>Tiled Random Art Generator</title>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bulma@0.9.4/css/bulma.min.css">
+    <style>
+        body { text-align: center; margin-top: 50px; }
+        canvas { border: 1px solid #dbdbdb; margin: auto; display: block; }
+    </style>
+</head>
+<body>
+    <section class="section">
+        <div class="container">
+            <h1 class="title is-4">Tiled Random Art Generator</h1>
+            <form id="form" class="field is-grouped is-justify-content-center">
+                <div class="control">
+                    <input class="input" type="text" name="seed" placeholder="Enter seed" required>
+                </div>
+                <div class="select is-primary">
+                    <select id="size">
+                        <option>512</option>
+                        <option selected>1024</option>
+                        <option>2048</option>
+                        <option>4096</option>
+                    </select>
+                </div>
+                <div class="control">
+                    <button class="button is-primary" type="submit">Generate</button>
+                </div>
+                <div class="control">
+                    <button class="button" type="button" id="cancel">Cancel</button>
+                </div>
+            </form>
+            <canvas id="canvas" width="1024" height="1024"></canvas>
+        </div>
+    </section>
+
+    <script>
+        const form = document.getElementById('form');
+        const sizeSelect = document.getElementById('size');
+        const canvas = document.getElementById('canvas');
+        const ctx = canvas.getContext('2d');
+        const cancelBtn = document.getElementById('cancel');
+        let socket = null;
+
+        function drawTile(msg) {
+            const img = new Image();
+            img.onload = () => ctx.drawImage(img, msg.x, msg.y, msg.w, msg.h);
+            img.src = 'data:image/png;base64,' + msg.png;
+        }
+
+        form.onsubmit = (e) => {
+            e.preventDefault();
+            const seed = e.target.seed.value;
+            const size = parseInt(sizeSelect.value, 10);
+            canvas.width = size;
+            canvas.height = size;
+            ctx.clearRect(0, 0, size, size);
+
+            if (socket) socket.close();
+            const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            socket = new WebSocket(proto + '//' + location.host + '/tiles/ws?seed=' + encodeURIComponent(seed) + '&size=' + size);
+            socket.onmessage = (event) => drawTile(JSON.parse(event.data));
+        };
+
+        cancelBtn.onclick = () => {
+            if (socket) socket.close();
+        };
+    </script>
+</body>
+</html>
+`))
+
+func serveTiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	tilesTemplate.Execute(w, nil)
+}
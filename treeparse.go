@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTree parses the compact textual form produced by a ColorFunc's
+// String() method back into an expression tree. This is the inverse of
+// String(), which lets individuals be bookmarked, shared, and cloned by
+// round-tripping through text instead of needing a separate deep-copy
+// method per node type.
+func ParseTree(s string) (ColorFunc, error) {
+	s = strings.TrimSpace(s)
+
+	switch s {
+	case "VariableX":
+		return &VariableX{}, nil
+	case "VariableY":
+		return &VariableY{}, nil
+	}
+
+	idx := strings.Index(s, "(")
+	if idx == -1 || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("treeparse: invalid node %q", s)
+	}
+	name := s[:idx]
+	inner := s[idx+1 : len(s)-1]
+	args := splitTopLevel(inner, ',')
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+
+	if name == "Constant" {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("treeparse: Constant wants 3 args, got %d", len(args))
+		}
+		r, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		g, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		b, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		return &Constant{r: r, g: g, b: b}, nil
+	}
+
+	kv := kvArgs(args)
+
+	parseFloatArg := func(key string) (float64, error) {
+		return strconv.ParseFloat(kv[key], 64)
+	}
+	parseSub := func(key string) (ColorFunc, error) {
+		return ParseTree(kv[key])
+	}
+
+	switch name {
+	case "Sin":
+		phase, err := parseFloatArg("phase")
+		if err != nil {
+			return nil, err
+		}
+		freq, err := parseFloatArg("freq")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &Sin{phase: phase, freq: freq, sub: sub}, nil
+
+	case "Product":
+		left, err := parseSub("left")
+		if err != nil {
+			return nil, err
+		}
+		right, err := parseSub("right")
+		if err != nil {
+			return nil, err
+		}
+		return &Product{left: left, right: right}, nil
+
+	case "Mix":
+		w, err := parseFloatArg("w")
+		if err != nil {
+			return nil, err
+		}
+		animated, err := strconv.ParseBool(kv["animated"])
+		if err != nil {
+			return nil, err
+		}
+		left, err := parseSub("left")
+		if err != nil {
+			return nil, err
+		}
+		right, err := parseSub("right")
+		if err != nil {
+			return nil, err
+		}
+		return &Mix{w: w, animated: animated, left: left, right: right}, nil
+
+	case "Well":
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &Well{sub: sub}, nil
+
+	case "FractalNoise":
+		scale, err := parseFloatArg("scale")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &FractalNoise{scale: scale, sub: sub}, nil
+
+	case "HSL":
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &HSL{sub: sub}, nil
+
+	case "RGBToHSL":
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &RGBToHSL{sub: sub}, nil
+
+	case "HSLToRGB":
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &HSLToRGB{sub: sub}, nil
+
+	case "Hue":
+		shift, err := parseFloatArg("shift")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &Hue{shift: shift, sub: sub}, nil
+
+	case "Saturate":
+		factor, err := parseFloatArg("factor")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &Saturate{factor: factor, sub: sub}, nil
+
+	case "Rotate":
+		speed, err := parseFloatArg("speed")
+		if err != nil {
+			return nil, err
+		}
+		sub, err := parseSub("sub")
+		if err != nil {
+			return nil, err
+		}
+		return &Rotate{speed: speed, sub: sub}, nil
+	}
+
+	return nil, fmt.Errorf("treeparse: unknown node %q", name)
+}
+
+// splitTopLevel splits s on sep, ignoring any sep that falls inside
+// parentheses, so a nested node's own commas don't get treated as
+// separators between the outer node's arguments.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if s[i] == sep && depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+// kvArgs splits a list of "key=value" strings into a map, cutting only on
+// the first "=" so a nested node's own "key=value" pairs inside value stay
+// intact.
+func kvArgs(args []string) map[string]string {
+	m := make(map[string]string, len(args))
+	for _, a := range args {
+		key, value, _ := strings.Cut(a, "=")
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return m
+}
+
+// cloneTree deep-copies a tree by round-tripping it through String() and
+// ParseTree, so mutation/crossover never aliases a parent's nodes.
+func cloneTree(cf ColorFunc) ColorFunc {
+	clone, err := ParseTree(cf.String())
+	if err != nil {
+		// String() is always parseable by construction; if this ever
+		// fires it's a bug in one of the two, not bad user input.
+		panic(fmt.Sprintf("treeparse: cloneTree could not round-trip %q: %v", cf.String(), err))
+	}
+	return clone
+}